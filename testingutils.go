@@ -3,101 +3,459 @@
 package testingutils
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 )
 
-//const EPSILON = .0000000000001
-const EPSILON = .00001
+// FloatPrecision is the number of decimal places floats are rounded to
+// before being compared. It replaces the old fixed EPSILON and correctly
+// handles values of vastly different magnitudes.
+var FloatPrecision = 5
+
+// NilSlicesAreEmpty, when true, treats a nil slice as equal to a
+// zero-length slice of the same type.
+var NilSlicesAreEmpty = false
+
+// NilMapsAreEmpty, when true, treats a nil map as equal to an empty
+// non-nil map of the same type.
+var NilMapsAreEmpty = false
+
+// CompareUnexportedFields controls whether unexported struct fields are
+// walked during comparison. When false, any field whose StructField.PkgPath
+// is non-empty is skipped.
+var CompareUnexportedFields = true
+
+// MaxDiff caps the number of Diffs collected by IsEqual/IsEqualLoopBreaker,
+// protecting callers against pathological inputs that would otherwise
+// produce an unbounded number of mismatches.
+var MaxDiff = 10
+
+// MaxDepth caps the recursion depth of IsEqualLoopBreaker, protecting
+// against pathological and cyclic inputs.
+var MaxDepth = 50
+
+// TimePrecision, when non-zero, is applied via time.Time.Truncate to both
+// operands before comparing time.Time values, so tests can tolerate
+// sub-second jitter from clocks or DB round-trips.
+var TimePrecision time.Duration = 0
+
+var timeType = reflect.TypeOf(time.Time{})
 
 type callHistoryElem struct {
 	a, b reflect.Value
 }
 
-// IsEqual recursively unpacks objects to examine whether they are deep equal
-// with EPSILON margin of error allowed for differences between float64 and
-// float32 components
-func IsEqual(a, b reflect.Value, t *testing.T) (bool, string) {
-	return IsEqualLoopBreaker(a, b, make(map[callHistoryElem]struct{}), t)
+// Equalities is a registry of per-type equality functions that
+// IsEqualLoopBreaker consults before falling back to its generic
+// reflective comparison. This lets callers cleanly compare types the
+// reflective walker can't handle well (time.Time with a tolerance,
+// *big.Int, protobuf messages, etc.) without forking the package.
+type Equalities map[reflect.Type]reflect.Value
+
+// AddFunc registers eqFunc as the comparator for its argument type.
+// eqFunc must have the shape func(T, T) bool; any other shape is
+// rejected.
+func (e Equalities) AddFunc(eqFunc interface{}) error {
+	fv := reflect.ValueOf(eqFunc)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		return fmt.Errorf("expected func, got %v", ft)
+	}
+	if ft.NumIn() != 2 || ft.In(0) != ft.In(1) {
+		return fmt.Errorf("expected func(T, T) bool, got %v", ft)
+	}
+	if ft.NumOut() != 1 || ft.Out(0).Kind() != reflect.Bool {
+		return fmt.Errorf("expected func(T, T) bool, got %v", ft)
+	}
+	e[ft.In(0)] = fv
+	return nil
+}
+
+// AddFuncs registers every function in eqFuncs via AddFunc, stopping at
+// the first invalid one.
+func (e Equalities) AddFuncs(eqFuncs ...interface{}) error {
+	for _, eqFunc := range eqFuncs {
+		if err := e.AddFunc(eqFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EqualitiesOrDie builds an Equalities from funcs, panicking if any of
+// them fail to register.
+func EqualitiesOrDie(funcs ...interface{}) Equalities {
+	e := Equalities{}
+	if err := e.AddFuncs(funcs...); err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Diff describes a single point of divergence found while walking two
+// values. Path is a dotted, index-annotated location such as
+// "MyStruct.Foo.Bar[3].Name".
+type Diff struct {
+	Path     string
+	Expected interface{}
+	Got      interface{}
+}
+
+// IsEqual recursively unpacks objects to examine whether they are deep
+// equal, with FloatPrecision decimal places of tolerance allowed for
+// differences between float64 and float32 components. It returns every
+// Diff found, rather than stopping at the first one.
+func IsEqual(a, b reflect.Value, t *testing.T) []Diff {
+	return IsEqualWith(a, b, nil, t)
+}
+
+// IsEqualWith behaves like IsEqual, but consults eqs for a custom
+// comparator before falling back to the generic reflective walk.
+func IsEqualWith(a, b reflect.Value, eqs Equalities, t *testing.T) []Diff {
+	return isEqualWith(a, b, eqs, FloatPrecision, MaxDiff, t)
+}
+
+// isEqualWith is IsEqualWith with floatPrecision/maxDiff threaded in
+// explicitly, rather than read from the FloatPrecision/MaxDiff package
+// globals. This is what lets RunCases honor per-call overrides even when
+// a case runs in a parallel subtest (see isEqualLoopBreaker).
+func isEqualWith(a, b reflect.Value, eqs Equalities, floatPrecision, maxDiff int, t *testing.T) []Diff {
+	root := ""
+	if a.IsValid() && a.Kind() == reflect.Struct {
+		root = a.Type().Name()
+	}
+	return isEqualLoopBreaker(a, b, make(map[callHistoryElem]struct{}), root, 0, eqs, floatPrecision, maxDiff, t)
 }
 
 // IsEqualLoopBreaker does the work of IsEqual, keeping track of all the
-// recursive calls it's made so far, thus avoiding loops
-func IsEqualLoopBreaker(a, b reflect.Value, callHistory map[callHistoryElem]struct{}, t *testing.T) (bool, string) {
+// recursive calls it's made so far (thus avoiding loops), the dotted path
+// to the current value, and the current recursion depth.
+func IsEqualLoopBreaker(a, b reflect.Value, callHistory map[callHistoryElem]struct{}, path string, depth int, eqs Equalities, t *testing.T) []Diff {
+	return isEqualLoopBreaker(a, b, callHistory, path, depth, eqs, FloatPrecision, MaxDiff, t)
+}
+
+// isEqualLoopBreaker is IsEqualLoopBreaker with floatPrecision/maxDiff
+// threaded in as explicit parameters instead of being read from the
+// FloatPrecision/MaxDiff package globals, so a caller like RunCases can
+// apply a per-call override without mutating shared state that a
+// t.Parallel() subtest might still be reading after the override would
+// otherwise have been restored.
+func isEqualLoopBreaker(a, b reflect.Value, callHistory map[callHistoryElem]struct{}, path string, depth int, eqs Equalities, floatPrecision, maxDiff int, t *testing.T) []Diff {
 	//if there's loop then we haven't found a problem so far
 	if _, exists := callHistory[callHistoryElem{a, b}]; exists {
-		return true, ""
+		return nil
+	}
+
+	//protect against pathological/cyclic inputs blowing the stack
+	if depth > MaxDepth {
+		return []Diff{{Path: path, Expected: "<max depth exceeded>", Got: "<max depth exceeded>"}}
+	}
+
+	//if a custom comparator is registered for this type, defer to it
+	//before the kind switch below. fn.Call panics if a/b were reached by
+	//walking into an unexported struct field (CompareUnexportedFields
+	//defaults to true), so fall back to safeEqual in that case rather than
+	//invoking the registered func via reflection.
+	if eqs != nil && a.IsValid() && b.IsValid() && a.Type() == b.Type() {
+		if fn, ok := eqs[a.Type()]; ok {
+			if !a.CanInterface() || !b.CanInterface() {
+				if !safeEqual(a, b) {
+					return []Diff{{Path: path, Expected: safeRepr(a), Got: safeRepr(b)}}
+				}
+				return nil
+			}
+			out := fn.Call([]reflect.Value{a, b})
+			if !out[0].Bool() {
+				return []Diff{{Path: path, Expected: safeRepr(a), Got: safeRepr(b)}}
+			}
+			return nil
+		}
+	}
+
+	//if the type exposes an Equal(T) bool or Compare(T) int method, prefer
+	//it over reflecting into (possibly private) fields; this is what makes
+	//time.Time, net.IP, netip.Addr, decimal.Decimal etc. compare correctly.
+	//a.Method(...).Call panics if a/b were reached by walking into an
+	//unexported struct field (CompareUnexportedFields defaults to true),
+	//so fall back to safeEqual in that case rather than calling the method.
+	if a.IsValid() && b.IsValid() && a.Type() == b.Type() {
+		if !a.CanInterface() || !b.CanInterface() {
+			if hasMethodEqualOrCompare(a.Type()) {
+				if !safeEqual(a, b) {
+					return []Diff{{Path: path, Expected: safeRepr(a), Got: safeRepr(b)}}
+				}
+				return nil
+			}
+		} else if equal, tried := tryMethodEqual(a, b); tried {
+			if !equal {
+				return []Diff{{Path: path, Expected: safeRepr(a), Got: safeRepr(b)}}
+			}
+			return nil
+		}
 	}
 
 	//if a and b aren't the same thing => not equal
 	if a.Kind() != b.Kind() {
-		return false, "Not same types"
+		return []Diff{{Path: path, Expected: a.Kind().String(), Got: b.Kind().String()}}
 	}
 
-	//if a and b are pointers indirect to their values
-	if a.Kind() == reflect.Ptr && !a.IsNil() && !b.IsNil() {
-		a = a.Elem()
-		b = b.Elem()
+	//if a and b are pointers indirect to their values. Recurse rather than
+	//reassigning a/b and falling through: the custom-comparator and
+	//Equal/Compare dispatch above key off a.Type(), so a *T field (the
+	//common shape for *time.Time, *big.Int, or a by-value Equalities
+	//registration reached through a pointer) needs those checks re-run
+	//against T, not the raw struct walk below.
+	if a.Kind() == reflect.Ptr {
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() && b.IsNil() {
+				return nil
+			}
+			return []Diff{{Path: path, Expected: safeRepr(a), Got: safeRepr(b)}}
+		}
+		return isEqualLoopBreaker(a.Elem(), b.Elem(), callHistory, path, depth+1, eqs, floatPrecision, maxDiff, t)
 	}
 
-	//if a & b are float64 see whether they are within EPSILON of each other
+	//if a & b are floats see whether they round to the same value at
+	//floatPrecision decimal places
 	if a.Kind() == reflect.Float64 || a.Kind() == reflect.Float32 {
-		//calculate difference between a and b
-		diff := a.Float() - b.Float()
-		//return whether diff is smaller than EPSILON (but not sure if diff is negative of positive)
-		ok := (diff < EPSILON) && (-diff < EPSILON)
-		var msg string
-		if !ok {
-			msg = fmt.Sprintf("Failing on a floating-point comparison: %f != %f\n", a.Float(), b.Float())
+		af := fmt.Sprintf("%.*f", floatPrecision, a.Float())
+		bf := fmt.Sprintf("%.*f", floatPrecision, b.Float())
+		if af != bf {
+			msg := fmt.Sprintf("Failing on a floating-point comparison: %f != %f\n", a.Float(), b.Float())
 			t.Logf(msg)
+			return []Diff{{Path: path, Expected: a.Float(), Got: b.Float()}}
 		}
-		return ok, msg
+		return nil
 	}
 
 	// add this call to the call history
 	callHistory[callHistoryElem{a, b}] = struct{}{}
 
-	// if a and b are slices or structs, check their elements
+	// if a and b are slices or arrays, check their elements
 	if a.Kind() == reflect.Slice || a.Kind() == reflect.Array {
-		// iterate over members, returning false right away if any member is false
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() && !NilSlicesAreEmpty {
+			return []Diff{{Path: path, Expected: nilOrLenLabel(a, "slice"), Got: nilOrLenLabel(b, "slice")}}
+		}
 		if a.Len() != b.Len() {
-			return false, "Slices having different lengths"
+			return []Diff{{
+				Path:     path,
+				Expected: fmt.Sprintf("slice/array of length %d", a.Len()),
+				Got:      fmt.Sprintf("slice/array of length %d", b.Len()),
+			}}
 		}
+		var diffs []Diff
 		for i := 0; i < a.Len(); i++ {
-			pass, msg := IsEqualLoopBreaker(a.Index(i), b.Index(i), callHistory, t)
-			if !pass {
-				return false, msg
+			if len(diffs) >= maxDiff {
+				break
 			}
+			diffs = appendDiffs(diffs, isEqualLoopBreaker(a.Index(i), b.Index(i), callHistory, fmt.Sprintf("%s[%d]", path, i), depth+1, eqs, floatPrecision, maxDiff, t), maxDiff)
 		}
-		return true, ""
+		return diffs
 	}
 
-	// if a & b are structs, iterate over fields, returning false right away if any member is false
+	// if a & b are structs, iterate over fields, collecting a Diff for any
+	// mismatched field
 	if a.Kind() == reflect.Struct {
 		if a.NumField() != b.NumField() {
-			return false, "Number of fields in struct do not match"
+			return []Diff{{
+				Path:     path,
+				Expected: fmt.Sprintf("struct with %d fields", a.NumField()),
+				Got:      fmt.Sprintf("struct with %d fields", b.NumField()),
+			}}
 		}
+		var diffs []Diff
 		// iterate over struct's fields
 		for i := 0; i < a.NumField(); i++ {
-			ok, msg := IsEqualLoopBreaker(a.Field(i), b.Field(i), callHistory, t)
-			if !ok {
-				return false, msg
+			if len(diffs) >= maxDiff {
+				break
+			}
+			field := a.Type().Field(i)
+			if !CompareUnexportedFields && field.PkgPath != "" {
+				continue
 			}
+			diffs = appendDiffs(diffs, isEqualLoopBreaker(a.Field(i), b.Field(i), callHistory, joinPath(path, field.Name), depth+1, eqs, floatPrecision, maxDiff, t), maxDiff)
 		}
-		return true, ""
+		return diffs
 	}
 
-	//TODO: if a & b are maps, iterate over keys & values in case we need to compare floats
-	//Note, maps are already working well for some types because of fallthrough to reflect.DeepEqual
+	// if a & b are maps, iterate over keys & values so float-valued entries
+	// go through the same loop-breaker as everything else
+	if a.Kind() == reflect.Map {
+		if a.IsNil() && b.IsNil() {
+			return nil
+		}
+		if a.IsNil() != b.IsNil() && !NilMapsAreEmpty {
+			return []Diff{{Path: path, Expected: nilOrLenLabel(a, "map"), Got: nilOrLenLabel(b, "map")}}
+		}
+		if a.Len() != b.Len() {
+			return []Diff{{
+				Path:     path,
+				Expected: fmt.Sprintf("map of length %d", a.Len()),
+				Got:      fmt.Sprintf("map of length %d", b.Len()),
+			}}
+		}
+		var diffs []Diff
+		for _, k := range a.MapKeys() {
+			if len(diffs) >= maxDiff {
+				break
+			}
+			keyPath := fmt.Sprintf("%s[%v]", path, safeRepr(k))
+			bVal := b.MapIndex(k)
+			if !bVal.IsValid() {
+				diffs = appendDiffs(diffs, []Diff{{
+					Path:     keyPath,
+					Expected: safeRepr(a.MapIndex(k)),
+					Got:      "<missing key>",
+				}}, maxDiff)
+				continue
+			}
+			diffs = appendDiffs(diffs, isEqualLoopBreaker(a.MapIndex(k), bVal, callHistory, keyPath, depth+1, eqs, floatPrecision, maxDiff, t), maxDiff)
+		}
+		return diffs
+	}
 
 	//cover any unhandled case, like intentional ones (err, int, etc) and anything
 	//we missed by accident (is ok because DeepEqual is conservative)
-	ok := reflect.DeepEqual(a.Interface(), b.Interface())
-	if !ok {
-		return false, "reflect.DeepEqual failed"
+	if !safeEqual(a, b) {
+		return []Diff{{Path: path, Expected: safeRepr(a), Got: safeRepr(b)}}
+	}
+	return nil
+}
+
+// joinPath appends next to base, dot-separating them unless base is empty.
+func joinPath(base, next string) string {
+	if base == "" {
+		return next
+	}
+	return base + "." + next
+}
+
+// nilOrLenLabel describes v, a nil-able slice or map, as either
+// "<nil kind>" or "kind of length N", for use in nil-vs-empty Diffs.
+func nilOrLenLabel(v reflect.Value, kind string) string {
+	if v.IsNil() {
+		return fmt.Sprintf("<nil %s>", kind)
+	}
+	return fmt.Sprintf("%s of length %d", kind, v.Len())
+}
+
+// appendDiffs appends more to diffs, never growing diffs past maxDiff.
+func appendDiffs(diffs []Diff, more []Diff, maxDiff int) []Diff {
+	for _, d := range more {
+		if len(diffs) >= maxDiff {
+			break
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
+
+// safeRepr returns a representation of v suitable for exposing in a Diff.
+// v.Interface() panics when v was read from an unexported struct field (the
+// path CompareUnexportedFields opts into), so safeRepr falls back to
+// formatting v with fmt, which is able to print unexported data without
+// needing to call Interface() itself.
+func safeRepr(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	if !v.CanInterface() {
+		return fmt.Sprintf("%v", v)
+	}
+	return v.Interface()
+}
+
+// safeEqual reports whether a and b are equal. It prefers reflect.DeepEqual,
+// but a and b may have been read from an unexported struct field, in which
+// case Interface() (and so DeepEqual) would panic; it then falls back to a
+// kind-appropriate reflect accessor, or else fmt's value formatting, neither
+// of which require CanInterface.
+func safeEqual(a, b reflect.Value) bool {
+	if a.CanInterface() && b.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	default:
+		return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+	}
+}
+
+// hasMethodEqualOrCompare reports whether t exposes an Equal(T) bool or
+// Compare(T) int method, without calling it. Used to decide whether a
+// value unreachable via CanInterface should still fall back to safeEqual
+// (matching the semantics tryMethodEqual would apply if it could be
+// called) rather than being reflected into field-by-field.
+func hasMethodEqualOrCompare(t reflect.Type) bool {
+	if m, ok := t.MethodByName("Equal"); ok && isBinaryMethod(m, t, reflect.Bool) {
+		return true
+	}
+	if m, ok := t.MethodByName("Compare"); ok && isBinaryMethod(m, t, reflect.Int) {
+		return true
+	}
+	return false
+}
+
+// tryMethodEqual reports whether a.Type() exposes an Equal(T) bool or
+// Compare(T) int method, and if so, the result of calling it on a and b.
+// tried is false if neither method is present, in which case equal is
+// meaningless and the caller should fall back to something else.
+func tryMethodEqual(a, b reflect.Value) (equal, tried bool) {
+	if TimePrecision > 0 && a.Type() == timeType && a.CanInterface() && b.CanInterface() {
+		a = reflect.ValueOf(a.Interface().(time.Time).Truncate(TimePrecision))
+		b = reflect.ValueOf(b.Interface().(time.Time).Truncate(TimePrecision))
+	}
+
+	t := a.Type()
+	if m, ok := t.MethodByName("Equal"); ok && isBinaryMethod(m, t, reflect.Bool) {
+		out := a.Method(m.Index).Call([]reflect.Value{b})
+		return out[0].Bool(), true
+	}
+	if m, ok := t.MethodByName("Compare"); ok && isBinaryMethod(m, t, reflect.Int) {
+		out := a.Method(m.Index).Call([]reflect.Value{b})
+		return out[0].Int() == 0, true
+	}
+	return false, false
+}
+
+// isBinaryMethod reports whether m has the shape func(T) outKind, not
+// counting the receiver.
+func isBinaryMethod(m reflect.Method, t reflect.Type, outKind reflect.Kind) bool {
+	mt := m.Type
+	return mt.NumIn() == 2 && mt.In(1) == t && mt.NumOut() == 1 && mt.Out(0).Kind() == outKind
+}
+
+// rvals converts vals into a slice of reflect.Values suitable for use as
+// function arguments or expected results. If vals is a struct, each of its
+// fields becomes an element (with names carrying the field names);
+// otherwise vals itself becomes the sole element.
+func rvals(vals interface{}) (result []reflect.Value, names []string) {
+	valOfVals := reflect.ValueOf(vals)
+	if valOfVals.Kind() == reflect.Struct {
+		result = make([]reflect.Value, valOfVals.NumField())
+		names = make([]string, valOfVals.NumField())
+		// load them in
+		for i := 0; i < valOfVals.NumField(); i++ {
+			result[i] = valOfVals.Field(i)
+			names[i] = valOfVals.Type().Field(i).Name
+		}
+	} else {
+		result = make([]reflect.Value, 1)
+		result[0] = valOfVals
 	}
-	return true, ""
+	return
 }
 
 func PrintTruncated(val interface{}) string {
@@ -109,27 +467,21 @@ func PrintTruncated(val interface{}) string {
 }
 
 // RunTest runs test on func fnptr unsing invals as parameters and checking
-// for expectvals as results returns true if test ok, returns false if test fails
-func RunTest(fnptr, invals, expectvals interface{}, t *testing.T) (bool, string) {
-	rvals := func(vals interface{}) (result []reflect.Value, names []string) {
-		// figure out whether vals is a struct (if it is, we need to
-		// read each field of struct into slice elements of result
-		valOfVals := reflect.ValueOf(vals)
-		if valOfVals.Kind() == reflect.Struct {
-			result = make([]reflect.Value, valOfVals.NumField())
-			names = make([]string, valOfVals.NumField())
-			// load them in
-			for i := 0; i < valOfVals.NumField(); i++ {
-				result[i] = valOfVals.Field(i)
-				names[i] = valOfVals.Type().Field(i).Name
-			}
-		} else {
-			result = make([]reflect.Value, 1)
-			result[0] = valOfVals
-		}
-		return
-	}
+// for expectvals as results. It returns true if test ok, along with every
+// Diff found if the test fails.
+func RunTest(fnptr, invals, expectvals interface{}, t *testing.T) (bool, []Diff) {
+	return RunTestWith(fnptr, invals, expectvals, nil, t)
+}
 
+// RunTestWith behaves like RunTest, but compares results using eqs, a
+// registry of custom per-type equality functions.
+func RunTestWith(fnptr, invals, expectvals interface{}, eqs Equalities, t *testing.T) (bool, []Diff) {
+	return runTestWith(fnptr, invals, expectvals, eqs, FloatPrecision, MaxDiff, t)
+}
+
+// runTestWith is RunTestWith with floatPrecision/maxDiff threaded in
+// explicitly; see isEqualLoopBreaker for why.
+func runTestWith(fnptr, invals, expectvals interface{}, eqs Equalities, floatPrecision, maxDiff int, t *testing.T) (bool, []Diff) {
 	//obtain function/value reflect thing from fn
 	fn := reflect.ValueOf(fnptr)
 
@@ -140,21 +492,18 @@ func RunTest(fnptr, invals, expectvals interface{}, t *testing.T) (bool, string)
 
 	if len(in) != fn.Type().NumIn() {
 		t.Fatal("The number of in params doesn't match function parameters.")
-		return false, ""
+		return false, nil
 	}
 	got := fn.Call(in)
 	if len(got) != fn.Type().NumOut() {
 		t.Fatal("The number of expect params doesn't match function results.")
-		return false, ""
+		return false, nil
 	}
 
-	var (
-		pass = true
-		msg  = ""
-	)
+	var diffs []Diff
 	for i := 0; i < len(got); i++ {
-		pass, msg = IsEqual(got[i], expect[i], t)
-		if !pass {
+		d := isEqualWith(expect[i], got[i], eqs, floatPrecision, maxDiff, t)
+		if len(d) > 0 {
 			//if this function returns more than one result, figure out the name of problem result
 			var name string
 			if len(got) > 1 {
@@ -162,13 +511,16 @@ func RunTest(fnptr, invals, expectvals interface{}, t *testing.T) (bool, string)
 			}
 			t.Logf("Expected%s: %s\n\n", name, PrintTruncated(expect[i]))
 			t.Logf("Got     %s: %s\n\n", name, PrintTruncated(got[i]))
-			return false, msg
+			diffs = append(diffs, d...)
 		}
 	}
-	return true, ""
+	return len(diffs) == 0, diffs
 }
 
-// RunAllTests runs battery of all tests provided
+// RunAllTests runs battery of all tests provided. It's a thin,
+// backward-compatible wrapper around RunCases that generates numeric case
+// names, so callers migrating away from it get the same t.Run subtest
+// isolation and -run filtering for free.
 func RunAllTests(fnptr, allInVals, allExpectVals interface{}, t *testing.T) {
 	fracture := func(blob interface{}) (result []interface{}) {
 		result = make([]interface{}, reflect.ValueOf(blob).Len())
@@ -195,11 +547,184 @@ func RunAllTests(fnptr, allInVals, allExpectVals interface{}, t *testing.T) {
 		t.Fatal("Number of input tests doesn't match number of expected results")
 		return
 	}
-	for i := 0; i < len(allIn); i++ {
-		t.Logf("Testing case %v\n", i)
-		pass, msg := RunTest(fnptr, allIn[i], allExpect[i], t)
-		if !pass {
-			t.Errorf("FAIL case %v (%s)\n", i, msg)
+	cases := make([]Case, len(allIn))
+	for i := range allIn {
+		cases[i] = Case{Name: fmt.Sprintf("%v", i), In: allIn[i], Expect: allExpect[i]}
+	}
+	RunCases(t, fnptr, cases)
+}
+
+// Case is a single named table-test entry for RunCases.
+type Case struct {
+	Name    string
+	In      interface{}
+	Expect  interface{}
+	Skip    bool
+	WantErr bool
+}
+
+// runCasesConfig holds the options accumulated from a RunCases call.
+type runCasesConfig struct {
+	eqs         Equalities
+	floatPrec   *int
+	maxDiff     *int
+	parallel    bool
+	errorReturn bool
+}
+
+// Option configures a RunCases call.
+type Option func(*runCasesConfig)
+
+// WithParallel makes every case call t.Parallel() inside its subtest.
+func WithParallel() Option {
+	return func(c *runCasesConfig) { c.parallel = true }
+}
+
+// WithEqualities registers eqs as the custom per-type comparators used for
+// every case.
+func WithEqualities(eqs Equalities) Option {
+	return func(c *runCasesConfig) { c.eqs = eqs }
+}
+
+// WithFloatPrecision overrides the package-level FloatPrecision for every
+// case in this RunCases call, including cases running in parallel
+// subtests via WithParallel.
+func WithFloatPrecision(precision int) Option {
+	return func(c *runCasesConfig) { c.floatPrec = &precision }
+}
+
+// WithMaxDiff overrides the package-level MaxDiff for every case in this
+// RunCases call, including cases running in parallel subtests via
+// WithParallel.
+func WithMaxDiff(maxDiff int) Option {
+	return func(c *runCasesConfig) { c.maxDiff = &maxDiff }
+}
+
+// WithErrorReturn declares that fnptr's last return value is an error.
+// Instead of comparing it field-by-field, RunCases checks it against
+// c.WantErr and, if Expect's corresponding value is itself a non-nil
+// error, compares the two with errors.Is.
+func WithErrorReturn() Option {
+	return func(c *runCasesConfig) { c.errorReturn = true }
+}
+
+// RunCases runs fnptr once per Case, each under its own t.Run(c.Name, ...)
+// subtest so failures surface with a readable path and can be filtered
+// with -run. It supersedes RunAllTests.
+func RunCases(t *testing.T, fnptr interface{}, cases []Case, opts ...Option) {
+	cfg := &runCasesConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Resolve the effective precision/maxDiff once and pass them down to
+	// each comparison explicitly, rather than overriding the package
+	// globals for the duration of the call: a subtest that calls
+	// t.Parallel() (WithParallel) only runs its body after RunCases
+	// itself has returned, by which point a deferred global restore would
+	// already have undone the override.
+	floatPrecision := FloatPrecision
+	if cfg.floatPrec != nil {
+		floatPrecision = *cfg.floatPrec
+	}
+	maxDiff := MaxDiff
+	if cfg.maxDiff != nil {
+		maxDiff = *cfg.maxDiff
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			if c.Skip {
+				t.Skip()
+			}
+			if cfg.parallel {
+				t.Parallel()
+			}
+			if cfg.errorReturn {
+				runCaseWithErrorReturn(fnptr, c, cfg.eqs, floatPrecision, maxDiff, t)
+				return
+			}
+			pass, diffs := runTestWith(fnptr, c.In, c.Expect, cfg.eqs, floatPrecision, maxDiff, t)
+			if !pass {
+				for _, d := range diffs {
+					t.Errorf("at %s: expected %v, got %v\n", d.Path, d.Expected, d.Got)
+				}
+			}
+		})
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// lastReturnIsError reports whether fn's final return value is declared as
+// the error interface. fn.Out(idx).Implements(errorType) alone would also
+// accept a concrete type (e.g. kind Struct) that merely satisfies the error
+// interface, and the caller's IsNil check panics on anything but an
+// interface/pointer/etc. kind, so the return slot itself must be the
+// interface.
+func lastReturnIsError(fn reflect.Type) bool {
+	if fn.NumOut() == 0 {
+		return false
+	}
+	out := fn.Out(fn.NumOut() - 1)
+	return out.Kind() == reflect.Interface && out.Implements(errorType)
+}
+
+// runCaseWithErrorReturn runs fnptr for a single Case whose last return
+// value is an error, checking it against c.WantErr (and, via errors.Is,
+// against an expected error carried in c.Expect) before comparing the
+// remaining return values as usual.
+func runCaseWithErrorReturn(fnptr interface{}, c Case, eqs Equalities, floatPrecision, maxDiff int, t *testing.T) {
+	fn := reflect.ValueOf(fnptr)
+
+	in, _ := rvals(c.In)
+	if len(in) != fn.Type().NumIn() {
+		t.Fatal("The number of in params doesn't match function parameters.")
+		return
+	}
+
+	got := fn.Call(in)
+	if len(got) == 0 || !lastReturnIsError(fn.Type()) {
+		t.Fatal("WithErrorReturn requires fnptr's last return value to be declared as error")
+		return
+	}
+	errIdx := len(got) - 1
+
+	var gotErr error
+	if !got[errIdx].IsNil() {
+		gotErr = got[errIdx].Interface().(error)
+	}
+
+	expect, exNames := rvals(c.Expect)
+	var wantErr error
+	if errIdx < len(expect) {
+		if e, ok := expect[errIdx].Interface().(error); ok {
+			wantErr = e
+		}
+	}
+
+	switch {
+	case c.WantErr && gotErr == nil:
+		t.Errorf("expected an error, got nil")
+	case !c.WantErr && gotErr != nil:
+		t.Errorf("expected no error, got %v", gotErr)
+	case wantErr != nil && !errors.Is(gotErr, wantErr):
+		t.Errorf("expected error %v, got %v", wantErr, gotErr)
+	}
+
+	for i := 0; i < errIdx; i++ {
+		d := isEqualWith(expect[i], got[i], eqs, floatPrecision, maxDiff, t)
+		if len(d) > 0 {
+			var name string
+			if len(got) > 1 {
+				name = " (" + exNames[i] + ")"
+			}
+			t.Logf("Expected%s: %s\n\n", name, PrintTruncated(expect[i]))
+			t.Logf("Got     %s: %s\n\n", name, PrintTruncated(got[i]))
+			for _, dd := range d {
+				t.Errorf("at %s: expected %v, got %v\n", dd.Path, dd.Expected, dd.Got)
+			}
 		}
 	}
 }
@@ -0,0 +1,401 @@
+package testingutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIsEqualMapFloat64(t *testing.T) {
+	a := map[string]float64{"x": 1.000001, "y": 2.5}
+	b := map[string]float64{"x": 1.000002, "y": 2.5}
+
+	diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t)
+	if len(diffs) != 0 {
+		t.Errorf("expected maps within FloatPrecision to be equal, got diffs: %+v", diffs)
+	}
+
+	c := map[string]float64{"x": 1.1, "y": 2.5}
+	diffs = IsEqual(reflect.ValueOf(a), reflect.ValueOf(c), t)
+	if len(diffs) != 1 {
+		t.Fatalf("expected exactly one diff, got %+v", diffs)
+	}
+	if diffs[0].Path != "[x]" {
+		t.Errorf("expected diff path \"[x]\", got %q", diffs[0].Path)
+	}
+}
+
+func TestIsEqualNestedMapFloat32(t *testing.T) {
+	a := map[string]map[string]float32{
+		"outer": {"inner": 1.000001},
+	}
+	b := map[string]map[string]float32{
+		"outer": {"inner": 1.000002},
+	}
+
+	diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t)
+	if len(diffs) != 0 {
+		t.Errorf("expected nested maps within FloatPrecision to be equal, got diffs: %+v", diffs)
+	}
+
+	c := map[string]map[string]float32{
+		"outer": {"inner": 2.0},
+	}
+	diffs = IsEqual(reflect.ValueOf(a), reflect.ValueOf(c), t)
+	if len(diffs) != 1 {
+		t.Fatalf("expected exactly one diff, got %+v", diffs)
+	}
+	if diffs[0].Path != "[outer][inner]" {
+		t.Errorf("expected diff path \"[outer][inner]\", got %q", diffs[0].Path)
+	}
+}
+
+func TestIsEqualMapMissingKey(t *testing.T) {
+	a := map[string]int{"x": 1, "y": 2}
+	b := map[string]int{"x": 1, "z": 2}
+
+	diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t)
+	if len(diffs) != 1 {
+		t.Fatalf("expected exactly one diff for mismatched keys, got %+v", diffs)
+	}
+}
+
+func TestIsEqualNilMaps(t *testing.T) {
+	var a, b map[string]int
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 0 {
+		t.Errorf("expected two nil maps to be equal, got diffs: %+v", diffs)
+	}
+}
+
+func TestIsEqualNilVsEmptyMap(t *testing.T) {
+	var a map[string]int
+	b := map[string]int{}
+
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 1 {
+		t.Fatalf("expected nil vs empty map to differ by default, got diffs: %+v", diffs)
+	}
+
+	NilMapsAreEmpty = true
+	defer func() { NilMapsAreEmpty = false }()
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 0 {
+		t.Errorf("expected nil vs empty map to be equal with NilMapsAreEmpty, got diffs: %+v", diffs)
+	}
+}
+
+func TestIsEqualNilVsEmptySlice(t *testing.T) {
+	var a []int
+	b := []int{}
+
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 1 {
+		t.Fatalf("expected nil vs empty slice to differ by default, got diffs: %+v", diffs)
+	}
+
+	NilSlicesAreEmpty = true
+	defer func() { NilSlicesAreEmpty = false }()
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 0 {
+		t.Errorf("expected nil vs empty slice to be equal with NilSlicesAreEmpty, got diffs: %+v", diffs)
+	}
+}
+
+type point struct {
+	X, Y int
+}
+
+func pointsCloseEnough(a, b point) bool {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx*dx+dy*dy <= 4
+}
+
+func TestAddFuncRejectsBadShapes(t *testing.T) {
+	eqs := Equalities{}
+
+	if err := eqs.AddFunc(func(a int, b string) bool { return true }); err == nil {
+		t.Error("expected an error for mismatched argument types")
+	}
+	if err := eqs.AddFunc(func(a, b int) (bool, error) { return true, nil }); err == nil {
+		t.Error("expected an error for a non-bool sole return")
+	}
+	if err := eqs.AddFunc("not a func"); err == nil {
+		t.Error("expected an error for a non-func value")
+	}
+	if err := eqs.AddFunc(pointsCloseEnough); err != nil {
+		t.Errorf("expected a valid func(T, T) bool to register cleanly, got: %v", err)
+	}
+}
+
+func TestEqualitiesOrDiePanicsOnBadFunc(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected EqualitiesOrDie to panic on an invalid func")
+		}
+	}()
+	EqualitiesOrDie(func(a int, b string) bool { return true })
+}
+
+func TestIsEqualWithCustomComparator(t *testing.T) {
+	eqs := EqualitiesOrDie(pointsCloseEnough)
+
+	a := point{X: 1, Y: 1}
+	b := point{X: 2, Y: 2}
+	if diffs := IsEqualWith(reflect.ValueOf(a), reflect.ValueOf(b), eqs, t); len(diffs) != 0 {
+		t.Errorf("expected points within tolerance to be equal, got diffs: %+v", diffs)
+	}
+
+	c := point{X: 10, Y: 10}
+	if diffs := IsEqualWith(reflect.ValueOf(a), reflect.ValueOf(c), eqs, t); len(diffs) != 1 {
+		t.Errorf("expected points outside tolerance to yield exactly one diff, got: %+v", diffs)
+	}
+}
+
+type withUnexportedPoint struct {
+	Label string
+	p     point
+}
+
+// TestIsEqualWithCustomComparatorOnUnexportedField proves that a registered
+// comparator for a type reached via an unexported field (CompareUnexportedFields
+// defaults to true) no longer panics. fn.Call can't be invoked on values read
+// from an unexported field, so this path falls back to safeEqual rather than
+// the registered func, meaning struct equality here is exact rather than
+// tolerant — it still must not panic.
+func TestIsEqualWithCustomComparatorOnUnexportedField(t *testing.T) {
+	eqs := EqualitiesOrDie(pointsCloseEnough)
+
+	a := withUnexportedPoint{Label: "a", p: point{X: 1, Y: 1}}
+	b := withUnexportedPoint{Label: "a", p: point{X: 1, Y: 1}}
+	if diffs := IsEqualWith(reflect.ValueOf(a), reflect.ValueOf(b), eqs, t); len(diffs) != 0 {
+		t.Errorf("expected identical unexported points to be equal, got diffs: %+v", diffs)
+	}
+
+	c := withUnexportedPoint{Label: "a", p: point{X: 10, Y: 10}}
+	if diffs := IsEqualWith(reflect.ValueOf(a), reflect.ValueOf(c), eqs, t); len(diffs) != 1 {
+		t.Errorf("expected differing unexported points to yield exactly one diff, got: %+v", diffs)
+	}
+}
+
+type withPointPtr struct {
+	Label string
+	P     *point
+}
+
+// TestIsEqualWithCustomComparatorOnPointerField proves that a registered
+// comparator still applies through a *T field (the common shape for types
+// with pointer-receiver methods, like *big.Int), not just a bare T: the
+// dispatch keys off a.Type(), so without re-checking after pointer
+// indirection a *point field would fall straight into the raw struct walk
+// and never reach pointsCloseEnough.
+func TestIsEqualWithCustomComparatorOnPointerField(t *testing.T) {
+	eqs := EqualitiesOrDie(pointsCloseEnough)
+
+	p1, p2 := point{X: 1, Y: 1}, point{X: 2, Y: 2}
+	a := withPointPtr{Label: "a", P: &p1}
+	b := withPointPtr{Label: "a", P: &p2}
+	if diffs := IsEqualWith(reflect.ValueOf(a), reflect.ValueOf(b), eqs, t); len(diffs) != 0 {
+		t.Errorf("expected *point field within tolerance to compare equal via the registered comparator, got diffs: %+v", diffs)
+	}
+
+	p3 := point{X: 10, Y: 10}
+	c := withPointPtr{Label: "a", P: &p3}
+	if diffs := IsEqualWith(reflect.ValueOf(a), reflect.ValueOf(c), eqs, t); len(diffs) != 1 {
+		t.Errorf("expected *point field outside tolerance to yield exactly one diff, got: %+v", diffs)
+	}
+}
+
+func TestIsEqualTimeTruncation(t *testing.T) {
+	a := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := a.Add(400 * time.Millisecond)
+
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) == 0 {
+		t.Error("expected times 400ms apart to differ with no TimePrecision set")
+	}
+
+	TimePrecision = time.Second
+	defer func() { TimePrecision = 0 }()
+
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 0 {
+		t.Errorf("expected times within TimePrecision to be equal, got diffs: %+v", diffs)
+	}
+}
+
+type withUnexportedTime struct {
+	Name string
+	t    time.Time
+}
+
+// TestIsEqualUnexportedEqualMethodFieldDoesNotPanic proves that a type with
+// an Equal method (time.Time) reached via an unexported field no longer
+// panics. a.Method(...).Call can't be invoked on values read from an
+// unexported field, so this falls back to safeEqual rather than time.Time's
+// Equal method, meaning comparisons here are exact rather than monotonic-
+// aware — it still must not panic.
+func TestIsEqualUnexportedEqualMethodFieldDoesNotPanic(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	a := withUnexportedTime{Name: "x", t: now}
+	b := withUnexportedTime{Name: "x", t: now}
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 0 {
+		t.Errorf("expected identical unexported times to be equal, got diffs: %+v", diffs)
+	}
+
+	c := withUnexportedTime{Name: "x", t: now.Add(time.Hour)}
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(c), t); len(diffs) != 1 {
+		t.Errorf("expected differing unexported times to yield exactly one diff, got: %+v", diffs)
+	}
+}
+
+type withTimePtr struct {
+	Name string
+	T    *time.Time
+}
+
+// TestIsEqualExportedTimePtrFieldUsesEqualMethod proves that time.Time's
+// Equal method is used through a *time.Time field (the common shape: the
+// Equal/Compare dispatch keys off a.Type(), so without re-checking after
+// pointer indirection a *time.Time field would fall into the raw struct
+// walk and reflect into wall/ext/loc directly, reporting spurious diffs for
+// two instants that are Equal but differently represented).
+func TestIsEqualExportedTimePtrFieldUsesEqualMethod(t *testing.T) {
+	now := time.Now()
+	parsed, err := time.Parse(time.RFC3339Nano, now.Format(time.RFC3339Nano))
+	if err != nil {
+		t.Fatalf("time.Parse: %v", err)
+	}
+	if !now.Equal(parsed) {
+		t.Fatalf("fixture invalid: now and parsed should be Equal, got now=%v parsed=%v", now, parsed)
+	}
+
+	a := withTimePtr{Name: "x", T: &now}
+	b := withTimePtr{Name: "x", T: &parsed}
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 0 {
+		t.Errorf("expected *time.Time field to compare via Equal despite differing wall/monotonic/loc representation, got diffs: %+v", diffs)
+	}
+
+	other := now.Add(time.Hour)
+	c := withTimePtr{Name: "x", T: &other}
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(c), t); len(diffs) != 1 {
+		t.Errorf("expected differing *time.Time fields to yield exactly one diff, got: %+v", diffs)
+	}
+}
+
+type version struct {
+	major, minor int
+}
+
+func (v version) Compare(o version) int {
+	switch {
+	case v.major != o.major:
+		return v.major - o.major
+	default:
+		return v.minor - o.minor
+	}
+}
+
+func TestIsEqualCompareFallback(t *testing.T) {
+	a := version{major: 1, minor: 2}
+	b := version{major: 1, minor: 2}
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 0 {
+		t.Errorf("expected equal versions to compare equal, got diffs: %+v", diffs)
+	}
+
+	c := version{major: 1, minor: 3}
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(c), t); len(diffs) != 1 {
+		t.Errorf("expected differing versions to yield exactly one diff, got: %+v", diffs)
+	}
+}
+
+type withUnexported struct {
+	Name   string
+	secret int
+}
+
+func TestIsEqualUnexportedFieldDoesNotPanic(t *testing.T) {
+	a := withUnexported{Name: "x", secret: 1}
+	b := withUnexported{Name: "x", secret: 2}
+
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(b), t); len(diffs) != 1 {
+		t.Errorf("expected differing unexported fields to yield exactly one diff, got: %+v", diffs)
+	}
+
+	c := withUnexported{Name: "x", secret: 1}
+	if diffs := IsEqual(reflect.ValueOf(a), reflect.ValueOf(c), t); len(diffs) != 0 {
+		t.Errorf("expected equal unexported fields to compare equal, got diffs: %+v", diffs)
+	}
+}
+
+type addArgs struct {
+	A, B int
+}
+
+func add(a, b int) int { return a + b }
+
+func TestRunAllTestsDelegatesToRunCases(t *testing.T) {
+	// RunAllTests now goes through RunCases, so each case runs as its own
+	// numbered t.Run subtest rather than a single flat loop body.
+	if ok := t.Run("passing", func(t *testing.T) {
+		RunAllTests(add, []addArgs{{1, 1}, {2, 3}}, []int{2, 5}, t)
+	}); !ok {
+		t.Error("expected RunAllTests to pass when every case matches")
+	}
+}
+
+func TestRunCasesBasic(t *testing.T) {
+	RunCases(t, add, []Case{
+		{Name: "one_plus_one", In: addArgs{1, 1}, Expect: 2},
+		{Name: "two_plus_three", In: addArgs{2, 3}, Expect: 5},
+	})
+}
+
+var errNotFound = errors.New("not found")
+
+func lookup(key string) (int, error) {
+	if key == "missing" {
+		return 0, fmt.Errorf("lookup %q: %w", key, errNotFound)
+	}
+	return len(key), nil
+}
+
+type lookupResult struct {
+	N   int
+	Err error
+}
+
+func TestRunCasesWithErrorReturn(t *testing.T) {
+	RunCases(t, lookup, []Case{
+		{Name: "found", In: "hello", Expect: lookupResult{N: 5}},
+		{Name: "missing", In: "missing", Expect: lookupResult{Err: errNotFound}, WantErr: true},
+	}, WithErrorReturn())
+}
+
+type concreteErr struct{ msg string }
+
+func (e concreteErr) Error() string { return e.msg }
+
+// returnsConcreteError's last return satisfies the error interface without
+// being declared as one (kind Struct, not Interface).
+func returnsConcreteError(n int) (int, concreteErr) {
+	return n, concreteErr{msg: "boom"}
+}
+
+func TestLastReturnIsError(t *testing.T) {
+	if !lastReturnIsError(reflect.TypeOf(lookup)) {
+		t.Error("expected lookup's error-typed return to be recognized")
+	}
+	if lastReturnIsError(reflect.TypeOf(returnsConcreteError)) {
+		t.Error("expected a concrete (non-interface) error-shaped return to be rejected")
+	}
+	if lastReturnIsError(reflect.TypeOf(add)) {
+		t.Error("expected a function with no error return to be rejected")
+	}
+}
+
+func identity(f float64) float64 { return f }
+
+func TestRunCasesFloatPrecisionWithParallel(t *testing.T) {
+	// WithFloatPrecision must still apply once the subtest body actually
+	// runs, even though WithParallel defers that body until after
+	// RunCases itself has returned.
+	RunCases(t, identity, []Case{
+		{Name: "rounds_within_precision", In: 1.23, Expect: 1.231},
+	}, WithFloatPrecision(1), WithParallel())
+}